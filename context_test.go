@@ -0,0 +1,64 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// hangingServer holds every request for 2 seconds before responding, far
+// past the deadlines these tests set, so callers return once their
+// deadline or context is hit.
+func hangingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(2 * time.Second)
+		}))
+}
+
+func TestGetLogsContextCancel(t *testing.T) {
+	server := hangingServer()
+	defer server.Close()
+
+	zeus := &Zeus{ApiServ: server.URL, Token: "goZeus"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := zeus.GetLogsContext(ctx, "name", "attr", "pattern", 0, 1, 0, 10)
+	if err == nil {
+		t.Error("expected context deadline error, got nil")
+	}
+}
+
+func TestZeusDeadline(t *testing.T) {
+	server := hangingServer()
+	defer server.Close()
+
+	zeus := &Zeus{ApiServ: server.URL, Token: "goZeus", Deadline: 20 * time.Millisecond}
+
+	start := time.Now()
+	_, _, err := zeus.GetLogs("name", "attr", "pattern", 0, 1, 0, 10)
+	if err == nil {
+		t.Error("expected deadline error, got nil")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("GetLogs did not respect Zeus.Deadline")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// APIError is returned by a Zeus call that fails local validation before a
+// request is sent, or whose request gets a non-200 response from the
+// server. Transport errors (e.g. a refused connection or an expired
+// context) and malformed response bodies are returned as they are, not
+// wrapped in an APIError. Callers that need to react to a particular failure
+// (e.g. to retry) should use errors.Is/errors.As rather than matching on
+// Error()'s text.
+type APIError struct {
+	// Status is the HTTP status code, or 0 if the error was raised before
+	// a request was made (e.g. local validation).
+	Status int
+	// Code is a short machine-readable identifier, e.g. "empty_batch",
+	// "rate_limited", "invalid_argument".
+	Code string
+	// Message is the human-readable server message, or a locally
+	// generated description for validation errors.
+	Message string
+	// Field is the offending field path, set for validation errors
+	// (e.g. "logs", "metrics[2].points").
+	Field string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("zeus: %s (field %q): %s", e.Code, e.Field, e.Message)
+	}
+	return fmt.Sprintf("zeus: %s: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is an *APIError with the same Code, so that
+// errors.Is(err, ErrRateLimited) matches regardless of Message or Field.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// Sentinel errors retry loops and callers can compare against with
+// errors.Is.
+var (
+	// ErrEmptyBatch is returned when PostLogs/PostMetrics is given no
+	// entries to post.
+	ErrEmptyBatch = &APIError{Code: "empty_batch", Message: "batch is empty"}
+	// ErrInvalidArgument is returned when a call is missing a required
+	// field or given a malformed one.
+	ErrInvalidArgument = &APIError{Code: "invalid_argument", Message: "invalid argument"}
+	// ErrRateLimited is returned when Zeus responds 429.
+	ErrRateLimited = &APIError{Status: http.StatusTooManyRequests, Code: "rate_limited", Message: "rate limited"}
+	// ErrUnauthorized is returned when Zeus responds 401.
+	ErrUnauthorized = &APIError{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "unauthorized"}
+)
+
+// fieldError returns a copy of sentinel with Field set, for use by the
+// validators in validate.go.
+func fieldError(sentinel *APIError, field string) *APIError {
+	err := *sentinel
+	err.Field = field
+	return &err
+}
+
+// errorResponse is the shape of a Zeus error body, when it has one.
+// Responses that don't parse as this fall back to using the raw body as
+// the message.
+type errorResponse struct {
+	Code    string `json:"error_code"`
+	Message string `json:"message"`
+}
+
+// newAPIError builds an *APIError from a non-2xx HTTP response, mapping
+// well-known status codes onto the matching sentinel.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	var parsed errorResponse
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.Message == "" {
+		parsed.Message = string(body)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return &APIError{Status: resp.StatusCode, Code: ErrUnauthorized.Code, Message: parsed.Message}
+	case http.StatusTooManyRequests:
+		return &APIError{Status: resp.StatusCode, Code: ErrRateLimited.Code, Message: parsed.Message}
+	}
+
+	code := parsed.Code
+	if code == "" {
+		code = fmt.Sprintf("status_%d", resp.StatusCode)
+	}
+	return &APIError{Status: resp.StatusCode, Code: code, Message: parsed.Message}
+}
+
+// decodeResponse validates the HTTP status and decodes resp's body as
+// JSON into out.
+func decodeResponse(resp *http.Response, out interface{}) error {
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp, data)
+	}
+
+	return json.Unmarshal(data, out)
+}
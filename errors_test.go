@@ -0,0 +1,100 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestPostLogsErrEmptyBatch(t *testing.T) {
+	zeus := &Zeus{ApiServ: "http://unused", Token: "goZeus"}
+
+	_, err := zeus.PostLogs(LogList{})
+	if !errors.Is(err, ErrEmptyBatch) {
+		t.Errorf("got %v, want ErrEmptyBatch", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("expected *APIError")
+	}
+	if apiErr.Field != "logs" {
+		t.Errorf("Field = %q, want %q", apiErr.Field, "logs")
+	}
+}
+
+func TestGetLogsErrInvalidArgument(t *testing.T) {
+	zeus := &Zeus{ApiServ: "http://unused", Token: "goZeus"}
+
+	_, _, err := zeus.GetLogs("", "attr", "pattern", 0, 10, 0, 10)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestGetMetricValuesErrInvalidArgument(t *testing.T) {
+	zeus := &Zeus{ApiServ: "http://unused", Token: "goZeus"}
+
+	_, err := zeus.GetMetricValues("", "max", "age", "1s", 0, 10, "", 0, 10)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestDeleteMetricsErrUnauthorized(t *testing.T) {
+	param := url.Values{}
+	server, zeus := mock("/metrics/goZeus/name/", &param, 401, `{"error_code":"unauthorized","message":"bad token"}`)
+	defer server.Close()
+
+	_, err := zeus.DeleteMetrics("name")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("got %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestPostMetricsErrRateLimited(t *testing.T) {
+	logName := randString(5)
+	metrics := MetricList{
+		Name:    logName,
+		Columns: []string{"col1"},
+		Metrics: []Metric{{Point: []float64{1.1}}},
+	}
+	jsonStr, _ := json.Marshal(metrics)
+	param := url.Values{"metrics": {string(jsonStr)}}
+	server, zeus := mock("/metrics/goZeus/"+logName+"/", &param, 429, `{"message":"slow down"}`)
+	defer server.Close()
+
+	_, err := zeus.PostMetrics(metrics)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("got %v, want ErrRateLimited", err)
+	}
+}
+
+func TestPostMetricsErrInvalidArgumentOnColumnMismatch(t *testing.T) {
+	zeus := &Zeus{ApiServ: "http://unused", Token: "goZeus"}
+	metrics := MetricList{
+		Name:    "name",
+		Columns: []string{"col1", "col2"},
+		Metrics: []Metric{{Point: []float64{1.1}}},
+	}
+
+	_, err := zeus.PostMetrics(metrics)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("got %v, want ErrInvalidArgument", err)
+	}
+}
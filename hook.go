@@ -0,0 +1,47 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import "time"
+
+// Hook lets callers observe every request a Zeus makes without taking a
+// dependency on any particular metrics or tracing library. Implementations
+// must be safe for concurrent use, since BeforeRequest/AfterRequest may be
+// called from multiple goroutines (e.g. a LogWriter and a MetricWriter
+// sharing the same Zeus).
+type Hook interface {
+	// BeforeRequest is called immediately before method is issued against
+	// path (e.g. "POST", "/logs/goZeus/app/").
+	BeforeRequest(method, path string)
+	// AfterRequest is called once the request completes, successfully or
+	// not. status is 0 if the request never received a response (err will
+	// be non-nil in that case).
+	AfterRequest(method, path string, status int, duration time.Duration, err error)
+}
+
+// fireBefore calls BeforeRequest on every configured hook.
+func (z *Zeus) fireBefore(method, path string) {
+	for _, h := range z.Hooks {
+		h.BeforeRequest(method, path)
+	}
+}
+
+// fireAfter calls AfterRequest on every configured hook.
+func (z *Zeus) fireAfter(method, path string, status int, start time.Time, err error) {
+	duration := time.Since(start)
+	for _, h := range z.Hooks {
+		h.AfterRequest(method, path, status, duration, err)
+	}
+}
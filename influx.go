@@ -0,0 +1,209 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FromLineProtocol parses InfluxDB line protocol (as produced by Telegraf)
+// read from r into one MetricList per measurement. Tags are dropped and
+// non-numeric fields are skipped: Zeus metrics carry only numeric points.
+// Fields missing from a later line for a measurement already seen are
+// recorded as 0 to keep that MetricList's rows aligned with its columns.
+func FromLineProtocol(r io.Reader) ([]MetricList, error) {
+	byName := map[string]*MetricList{}
+	var order []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		measurement, fields, timestamp, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		list, ok := byName[measurement]
+		if !ok {
+			list = &MetricList{Name: measurement}
+			byName[measurement] = list
+			order = append(order, measurement)
+		}
+
+		point := make([]float64, len(list.Columns))
+		for key, value := range fields {
+			idx := columnIndex(list, key)
+			for idx >= len(point) {
+				point = append(point, 0)
+			}
+			point[idx] = value
+		}
+		list.Metrics = append(list.Metrics, Metric{Timestamp: timestamp, Point: point})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	lists := make([]MetricList, len(order))
+	for i, name := range order {
+		lists[i] = *byName[name]
+	}
+	return lists, nil
+}
+
+// columnIndex returns the index of column in list.Columns, appending it
+// (and widening every metric already recorded) if it hasn't been seen yet.
+func columnIndex(list *MetricList, column string) int {
+	for i, c := range list.Columns {
+		if c == column {
+			return i
+		}
+	}
+	list.Columns = append(list.Columns, column)
+	idx := len(list.Columns) - 1
+	for i := range list.Metrics {
+		for len(list.Metrics[i].Point) <= idx {
+			list.Metrics[i].Point = append(list.Metrics[i].Point, 0)
+		}
+	}
+	return idx
+}
+
+// splitUnquotedSpace splits line on whitespace, as strings.Fields does,
+// except that whitespace inside a double-quoted field value (e.g. a
+// Telegraf string field like error="connection timeout") is not treated
+// as a separator.
+func splitUnquotedSpace(line string) []string {
+	var parts []string
+	var b strings.Builder
+	inQuotes := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			b.WriteRune(r)
+		case r == ' ' && !inQuotes:
+			if b.Len() > 0 {
+				parts = append(parts, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		parts = append(parts, b.String())
+	}
+	return parts
+}
+
+// splitUnquotedComma splits s on commas, ignoring commas that appear
+// inside double-quoted field values.
+func splitUnquotedComma(s string) []string {
+	var parts []string
+	var b strings.Builder
+	inQuotes := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			b.WriteRune(r)
+		case r == ',' && !inQuotes:
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	parts = append(parts, b.String())
+	return parts
+}
+
+// parseLine parses a single non-blank, non-comment line protocol line into
+// its measurement name, numeric fields and timestamp in seconds.
+func parseLine(line string) (measurement string, fields map[string]float64, timestamp float64, err error) {
+	parts := splitUnquotedSpace(line)
+	if len(parts) < 2 {
+		return "", nil, 0, fmt.Errorf("zeus: malformed line protocol line: %q", line)
+	}
+
+	measurementTags := strings.SplitN(parts[0], ",", 2)
+	measurement = measurementTags[0]
+
+	fieldsPart := parts[1]
+	fields = map[string]float64{}
+	for _, kv := range splitUnquotedComma(fieldsPart) {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		value := strings.TrimSuffix(pair[1], "i")
+		value = strings.Trim(value, `"`)
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			// Non-numeric (string/boolean) fields aren't representable
+			// as a Zeus Point value; skip them.
+			continue
+		}
+		fields[pair[0]] = f
+	}
+
+	if len(parts) >= 3 {
+		ns, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return "", nil, 0, fmt.Errorf("zeus: malformed line protocol timestamp: %q", parts[2])
+		}
+		timestamp = float64(ns) / 1e9
+	}
+
+	return measurement, fields, timestamp, nil
+}
+
+// ToLineProtocol renders lists as InfluxDB line protocol, one line per
+// Metric, with timestamps in nanoseconds.
+func ToLineProtocol(lists []MetricList) ([]byte, error) {
+	var b strings.Builder
+	for _, list := range lists {
+		for _, metric := range list.Metrics {
+			b.WriteString(list.Name)
+			b.WriteByte(' ')
+			written := 0
+			for i, column := range list.Columns {
+				if i >= len(metric.Point) {
+					break
+				}
+				if written > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(column)
+				b.WriteByte('=')
+				b.WriteString(strconv.FormatFloat(metric.Point[i], 'f', -1, 64))
+				written++
+			}
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatInt(int64(metric.Timestamp*1e9), 10))
+			b.WriteByte('\n')
+		}
+	}
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromLineProtocol(t *testing.T) {
+	input := "cpu,host=server01 value=0.64,idle=0.10 1434055562000000000\n" +
+		"cpu,host=server01 value=0.72 1434055563000000000\n"
+
+	lists, err := FromLineProtocol(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 1 {
+		t.Fatalf("got %d metric lists, want 1", len(lists))
+	}
+
+	cpu := lists[0]
+	if cpu.Name != "cpu" {
+		t.Errorf("name = %q, want cpu", cpu.Name)
+	}
+	if len(cpu.Metrics) != 2 {
+		t.Fatalf("got %d metrics, want 2", len(cpu.Metrics))
+	}
+	if cpu.Metrics[0].Timestamp != 1434055562.0 {
+		t.Errorf("timestamp = %v, want 1434055562.0", cpu.Metrics[0].Timestamp)
+	}
+
+	valueIdx := -1
+	for i, c := range cpu.Columns {
+		if c == "value" {
+			valueIdx = i
+		}
+	}
+	if valueIdx == -1 {
+		t.Fatal("value column not found")
+	}
+	if cpu.Metrics[0].Point[valueIdx] != 0.64 {
+		t.Errorf("value = %v, want 0.64", cpu.Metrics[0].Point[valueIdx])
+	}
+	// idle is missing from the second line, so it should be padded with 0.
+	idleIdx := -1
+	for i, c := range cpu.Columns {
+		if c == "idle" {
+			idleIdx = i
+		}
+	}
+	if idleIdx != -1 && cpu.Metrics[1].Point[idleIdx] != 0 {
+		t.Errorf("idle on second point = %v, want 0", cpu.Metrics[1].Point[idleIdx])
+	}
+}
+
+func TestFromLineProtocolQuotedStringField(t *testing.T) {
+	input := `weather,location=us-midwest temperature=82,error="connection timeout" 1465839830100400200` + "\n"
+
+	lists, err := FromLineProtocol(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 1 {
+		t.Fatalf("got %d metric lists, want 1", len(lists))
+	}
+
+	weather := lists[0]
+	if weather.Name != "weather" {
+		t.Errorf("name = %q, want weather", weather.Name)
+	}
+	if len(weather.Metrics) != 1 {
+		t.Fatalf("got %d metrics, want 1", len(weather.Metrics))
+	}
+	if weather.Metrics[0].Timestamp != 1465839830.1004002 {
+		t.Errorf("timestamp = %v, want 1465839830.1004002", weather.Metrics[0].Timestamp)
+	}
+
+	tempIdx := -1
+	for i, c := range weather.Columns {
+		if c == "temperature" {
+			tempIdx = i
+		}
+		// error is a quoted string field, not representable as a Zeus
+		// Point value, and must be skipped rather than aborting the line.
+		if c == "error" {
+			t.Error("string field \"error\" should have been skipped, not added as a column")
+		}
+	}
+	if tempIdx == -1 {
+		t.Fatal("temperature column not found")
+	}
+	if weather.Metrics[0].Point[tempIdx] != 82 {
+		t.Errorf("temperature = %v, want 82", weather.Metrics[0].Point[tempIdx])
+	}
+}
+
+func TestToLineProtocolRoundTrip(t *testing.T) {
+	lists := []MetricList{{
+		Name:    "cpu",
+		Columns: []string{"value"},
+		Metrics: []Metric{{Timestamp: 1434055562.0, Point: []float64{0.64}}},
+	}}
+
+	data, err := ToLineProtocol(lists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	back, err := FromLineProtocol(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != 1 || back[0].Name != "cpu" || back[0].Metrics[0].Point[0] != 0.64 {
+		t.Errorf("round trip mismatch: %#v", back)
+	}
+}
+
+func TestToLineProtocolShortPointOmitsTrailingComma(t *testing.T) {
+	lists := []MetricList{{
+		Name:    "cpu",
+		Columns: []string{"a", "b"},
+		Metrics: []Metric{{Timestamp: 1.0, Point: []float64{5}}},
+	}}
+
+	data, err := ToLineProtocol(lists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "cpu a=5 1000000000\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+}
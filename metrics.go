@@ -0,0 +1,114 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusHook implements Hook and BatchObserver, exposing counters and
+// histograms for every Zeus call. A hook made with NewPrometheusHook is not
+// registered with any registry; use Register to create one whose
+// collectors are registered with a given registry.
+type PrometheusHook struct {
+	requestsTotal   *prometheus.CounterVec
+	failuresTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	batchSize       prometheus.Histogram
+	retriesTotal    prometheus.Counter
+}
+
+// NewPrometheusHook creates a PrometheusHook. Use Register to both create
+// and register one with a *prometheus.Registry in a single call.
+func NewPrometheusHook() *PrometheusHook {
+	return &PrometheusHook{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zeus",
+			Name:      "requests_total",
+			Help:      "Total number of requests made to the Zeus API, by HTTP method.",
+		}, []string{"method"}),
+		failuresTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zeus",
+			Name:      "request_failures_total",
+			Help:      "Total number of failed Zeus API requests, by HTTP method and status class.",
+		}, []string{"method", "status_class"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "zeus",
+			Name:      "request_duration_seconds",
+			Help:      "Zeus API request latency in seconds, by HTTP method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "zeus",
+			Name:      "writer_batch_size",
+			Help:      "Number of entries in each batch flushed by a LogWriter or MetricWriter.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}),
+		retriesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "zeus",
+			Name:      "writer_retries_total",
+			Help:      "Total number of batch flush attempts retried by a LogWriter or MetricWriter.",
+		}),
+	}
+}
+
+// Register creates a PrometheusHook and registers its collectors with
+// reg. The returned hook can be attached to a Zeus's Hooks and to a
+// LogWriter/MetricWriter's WriterOptions.Observer.
+func Register(reg *prometheus.Registry) (*PrometheusHook, error) {
+	h := NewPrometheusHook()
+	for _, c := range []prometheus.Collector{
+		h.requestsTotal, h.failuresTotal, h.requestDuration, h.batchSize, h.retriesTotal,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// BeforeRequest implements Hook.
+func (h *PrometheusHook) BeforeRequest(method, path string) {}
+
+// AfterRequest implements Hook.
+func (h *PrometheusHook) AfterRequest(method, path string, status int, duration time.Duration, err error) {
+	h.requestsTotal.WithLabelValues(method).Inc()
+	h.requestDuration.WithLabelValues(method).Observe(duration.Seconds())
+	if err != nil || status >= 400 {
+		h.failuresTotal.WithLabelValues(method, statusClass(status)).Inc()
+	}
+}
+
+// ObserveBatchSize implements BatchObserver.
+func (h *PrometheusHook) ObserveBatchSize(n int) {
+	h.batchSize.Observe(float64(n))
+}
+
+// ObserveRetry implements BatchObserver.
+func (h *PrometheusHook) ObserveRetry() {
+	h.retriesTotal.Inc()
+}
+
+// statusClass buckets an HTTP status code into "2xx", "4xx", "5xx", etc.
+// A status of 0 (no response received) is reported as "error".
+func statusClass(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPrometheusHookCountsRequests(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	hook, err := Register(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logName := randString(5)
+	log := Log{"timestamp": time.Now().Unix(), "message": "Message from Go"}
+	logs := LogList{Name: logName, Logs: []Log{log}}
+
+	jsonStr, _ := json.Marshal(logs)
+	param := url.Values{"logs": {string(jsonStr)}}
+
+	server, zeus := mock("/logs/goZeus/"+logName+"/", &param, 200, `{"successful": 1}`)
+	defer server.Close()
+	zeus.Hooks = []Hook{hook}
+
+	if _, err := zeus.PostLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, mf := range families {
+		if mf.GetName() == "zeus_requests_total" {
+			found = true
+			if got := metricValue(mf); got != 1 {
+				t.Errorf("zeus_requests_total = %v, want 1", got)
+			}
+		}
+	}
+	if !found {
+		t.Error("zeus_requests_total was not registered")
+	}
+}
+
+func TestPrometheusHookCountsRetries(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	hook, err := Register(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, zeus := flakyServer(2)
+	defer server.Close()
+
+	opts := DefaultWriterOptions()
+	opts.BatchSize = 1
+	opts.FlushInterval = 10 * time.Millisecond
+	opts.InitialBackoff = time.Millisecond
+	opts.MaxBackoff = 5 * time.Millisecond
+	opts.Observer = hook
+
+	w := NewLogWriter(zeus, "retry-test", opts)
+	w.Write(Log{"message": "hello"})
+	w.Close()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range families {
+		if mf.GetName() == "zeus_writer_retries_total" {
+			if got := metricValue(mf); got != 2 {
+				t.Errorf("zeus_writer_retries_total = %v, want 2", got)
+			}
+			return
+		}
+	}
+	t.Error("zeus_writer_retries_total was not registered")
+}
+
+func metricValue(mf *dto.MetricFamily) float64 {
+	m := mf.GetMetric()[0]
+	if c := m.GetCounter(); c != nil {
+		return c.GetValue()
+	}
+	if h := m.GetHistogram(); h != nil {
+		return float64(h.GetSampleCount())
+	}
+	return 0
+}
@@ -0,0 +1,158 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// metricNameLabel is the reserved Prometheus label carrying a series' name.
+const metricNameLabel = "__name__"
+
+// maxRemoteWriteBodySize caps the compressed request body RemoteWriteHandler
+// will read from a single request.
+const maxRemoteWriteBodySize = 16 << 20 // 16 MiB
+
+// maxRemoteWriteDecodedSize caps the decompressed size of a single request.
+// Snappy payloads can expand far beyond their compressed size, so the
+// compressed-body cap alone isn't enough to bound the work Decode does.
+const maxRemoteWriteDecodedSize = 64 << 20 // 64 MiB
+
+// FromRemoteWrite converts a Prometheus remote_write WriteRequest into one
+// MetricList per series (keyed by its __name__ label), each with a single
+// "value" column. Labels other than __name__ are dropped, since a Zeus
+// MetricList has no label dimension to carry them in.
+func FromRemoteWrite(req *prompb.WriteRequest) ([]MetricList, error) {
+	lists := make([]MetricList, 0, len(req.Timeseries))
+	for _, ts := range req.Timeseries {
+		name := ""
+		for _, l := range ts.Labels {
+			if l.Name == metricNameLabel {
+				name = l.Value
+				break
+			}
+		}
+
+		metrics := make([]Metric, len(ts.Samples))
+		for i, s := range ts.Samples {
+			metrics[i] = Metric{
+				Timestamp: float64(s.Timestamp) / 1e3,
+				Point:     []float64{s.Value},
+			}
+		}
+		lists = append(lists, MetricList{Name: name, Columns: []string{"value"}, Metrics: metrics})
+	}
+	return lists, nil
+}
+
+// ToRemoteWrite converts lists into a Prometheus remote_write WriteRequest,
+// emitting one series per (MetricList, column) pair, named
+// "<list.Name>_<column>", or just list.Name when the list has a single
+// column.
+func ToRemoteWrite(lists []MetricList) (*prompb.WriteRequest, error) {
+	req := &prompb.WriteRequest{}
+	for _, list := range lists {
+		for c, column := range list.Columns {
+			name := list.Name
+			if len(list.Columns) > 1 {
+				name = list.Name + "_" + column
+			}
+
+			samples := make([]prompb.Sample, 0, len(list.Metrics))
+			for _, metric := range list.Metrics {
+				if c >= len(metric.Point) {
+					continue
+				}
+				samples = append(samples, prompb.Sample{
+					Value:     metric.Point[c],
+					Timestamp: int64(metric.Timestamp * 1e3),
+				})
+			}
+
+			req.Timeseries = append(req.Timeseries, prompb.TimeSeries{
+				Labels:  []prompb.Label{{Name: metricNameLabel, Value: name}},
+				Samples: samples,
+			})
+		}
+	}
+	return req, nil
+}
+
+// RemoteWriteHandler returns an http.Handler that decodes snappy-compressed
+// protobuf WriteRequest bodies exactly as Prometheus sends them to a
+// remote_write endpoint, and forwards every series that has samples to z
+// through z.PostMetrics, so a Zeus deployment can be dropped into any
+// `remote_write:` block in a Prometheus config. Series with no samples are
+// skipped.
+func RemoteWriteHandler(z *Zeus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRemoteWriteBodySize)
+		compressed, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		decodedLen, err := snappy.DecodedLen(compressed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if decodedLen > maxRemoteWriteDecodedSize {
+			http.Error(w, "zeus: decompressed remote_write payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req prompb.WriteRequest
+		if err := req.Unmarshal(data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		lists, err := FromRemoteWrite(&req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for _, list := range lists {
+			if len(list.Metrics) == 0 {
+				continue
+			}
+			if _, err := z.PostMetrics(list); err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+func TestFromToRemoteWrite(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "cpu_usage"}},
+			Samples: []prompb.Sample{{Value: 0.64, Timestamp: 1434055562000}},
+		}},
+	}
+
+	lists, err := FromRemoteWrite(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 1 || lists[0].Name != "cpu_usage" || lists[0].Metrics[0].Point[0] != 0.64 {
+		t.Fatalf("unexpected conversion: %#v", lists)
+	}
+
+	back, err := ToRemoteWrite(lists)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Timeseries) != 1 || back.Timeseries[0].Samples[0].Value != 0.64 {
+		t.Errorf("round trip mismatch: %#v", back)
+	}
+}
+
+func TestRemoteWriteHandler(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "cpu_usage"}},
+			Samples: []prompb.Sample{{Value: 0.64, Timestamp: 1434055562000}},
+		}},
+	}
+	data, err := req.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed := snappy.Encode(nil, data)
+
+	lists, err := FromRemoteWrite(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonStr, _ := json.Marshal(lists[0])
+	param := url.Values{"metrics": {string(jsonStr)}}
+
+	server, zeus := mock("/metrics/goZeus/cpu_usage/", &param, 200, `{"successful": 1}`)
+	defer server.Close()
+
+	handler := RemoteWriteHandler(zeus)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/x-protobuf", bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+func TestRemoteWriteHandlerRejectsOversizedBody(t *testing.T) {
+	zeus := &Zeus{ApiServ: "http://unused", Token: "goZeus"}
+
+	handler := RemoteWriteHandler(zeus)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	oversized := bytes.Repeat([]byte{0}, maxRemoteWriteBodySize+1)
+	resp, err := http.Post(ts.URL, "application/x-protobuf", bytes.NewReader(oversized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestRemoteWriteHandlerRejectsOversizedDecodedPayload(t *testing.T) {
+	zeus := &Zeus{ApiServ: "http://unused", Token: "goZeus"}
+
+	handler := RemoteWriteHandler(zeus)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	// A highly compressible payload whose decoded size blows past
+	// maxRemoteWriteDecodedSize while staying well under
+	// maxRemoteWriteBodySize compressed.
+	huge := bytes.Repeat([]byte{0}, maxRemoteWriteDecodedSize+1)
+	compressed := snappy.Encode(nil, huge)
+
+	resp, err := http.Post(ts.URL, "application/x-protobuf", bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+}
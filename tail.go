@@ -0,0 +1,280 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Zeus has no WebSocket endpoint, so TailLogs and TailMetrics are
+// implemented as a long-poll loop over GetLogs/GetMetricValues, advancing
+// the query window by the last-seen timestamp.
+
+// LogQuery configures TailLogs.
+type LogQuery struct {
+	LogName       string
+	AttributeName string
+	Pattern       string
+	// From is the timestamp to start tailing from. Zero means now.
+	From int64
+	// PollInterval is how often the log window is re-queried. Zero means
+	// DefaultPollInterval.
+	PollInterval time.Duration
+	Limit        int
+}
+
+// MetricQuery configures TailMetrics.
+type MetricQuery struct {
+	MetricName          string
+	AggregatorFunction  string
+	AggregatorColumn    string
+	GroupInterval       string
+	FilterCondition     string
+	// From is the timestamp to start tailing from. Zero means now.
+	From float64
+	// PollInterval is how often the metric window is re-queried. Zero
+	// means DefaultPollInterval.
+	PollInterval time.Duration
+	Limit        int
+}
+
+// DefaultPollInterval is used by TailLogs/TailMetrics when a query doesn't
+// set PollInterval.
+const DefaultPollInterval = 2 * time.Second
+
+// tailBackoffOptions reuses WriterOptions.backoff's exponential-with-jitter
+// algorithm to space out retries after a failed poll.
+var tailBackoffOptions = WriterOptions{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 10 * time.Second}
+
+func tailBackoff(attempt int) time.Duration {
+	return tailBackoffOptions.backoff(attempt)
+}
+
+// sleepCtx waits for d or ctx.Done(), whichever comes first. It reports
+// whether the full wait elapsed.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// logDedupKey identifies a log by (timestamp, sequence_number) so
+// TailLogs can drop entries it has already delivered when two poll
+// windows overlap.
+func logDedupKey(log Log) string {
+	return fmt.Sprintf("%v|%v", log["timestamp"], log["sequence_number"])
+}
+
+// TailLogs maintains a long-poll loop over GetLogs, delivering new logs
+// matching query as they appear and reconnecting with backoff on
+// transient errors. Both channels are closed once ctx is done or ctx
+// times out; the error channel additionally receives one error per failed
+// poll attempt.
+func (z *Zeus) TailLogs(ctx context.Context, query LogQuery) (<-chan Log, <-chan error) {
+	logs := make(chan Log)
+	errs := make(chan error, 1)
+
+	interval := query.PollInterval
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+
+	go func() {
+		defer close(logs)
+		defer close(errs)
+
+		from := query.From
+		if from == 0 {
+			from = time.Now().Unix()
+		}
+		seen := map[string]int64{}
+		attempt := 0
+
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			now := time.Now().Unix()
+			_, result, err := z.GetLogsContext(ctx, query.LogName, query.AttributeName,
+				query.Pattern, from, now, 0, query.Limit)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case errs <- err:
+				case <-ctx.Done():
+					return
+				}
+				attempt++
+				if !sleepCtx(ctx, tailBackoff(attempt)) {
+					return
+				}
+				continue
+			}
+			attempt = 0
+
+			next := from
+			for _, log := range result.Logs {
+				key := logDedupKey(log)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				ts := from
+				if v, ok := log["timestamp"].(int64); ok {
+					ts = v
+				} else if v, ok := log["timestamp"].(float64); ok {
+					ts = int64(v)
+				}
+				if ts > next {
+					next = ts
+				}
+				seen[key] = ts
+
+				select {
+				case logs <- log:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// Only advance from when a later log was actually seen: if we
+			// jumped straight to now, a log timestamped inside this poll's
+			// window but indexed by Zeus after the poll ran would never be
+			// retried on the next iteration.
+			from = next
+			for k, ts := range seen {
+				if ts < from {
+					delete(seen, k)
+				}
+			}
+
+			if !sleepCtx(ctx, interval) {
+				return
+			}
+		}
+	}()
+
+	return logs, errs
+}
+
+// metricDedupKey identifies a Metric by (timestamp, sequence_number),
+// reading sequence_number out of columns/point if the query asked for it.
+func metricDedupKey(columns []string, m Metric) string {
+	seq := interface{}(nil)
+	for i, c := range columns {
+		if c == "sequence_number" && i < len(m.Point) {
+			seq = m.Point[i]
+			break
+		}
+	}
+	return fmt.Sprintf("%v|%v", m.Timestamp, seq)
+}
+
+// TailMetrics maintains a long-poll loop over GetMetricValues, delivering
+// new metrics matching query as they appear and reconnecting with backoff
+// on transient errors. Both channels are closed once ctx is done; the
+// error channel additionally receives one error per failed poll attempt.
+func (z *Zeus) TailMetrics(ctx context.Context, query MetricQuery) (<-chan Metric, <-chan error) {
+	metrics := make(chan Metric)
+	errs := make(chan error, 1)
+
+	interval := query.PollInterval
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+
+	go func() {
+		defer close(metrics)
+		defer close(errs)
+
+		from := query.From
+		if from == 0 {
+			from = float64(time.Now().Unix())
+		}
+		seen := map[string]float64{}
+		attempt := 0
+
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			now := float64(time.Now().Unix())
+			result, err := z.GetMetricValuesContext(ctx, query.MetricName, query.AggregatorFunction,
+				query.AggregatorColumn, query.GroupInterval, from, now, query.FilterCondition, 0, query.Limit)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case errs <- err:
+				case <-ctx.Done():
+					return
+				}
+				attempt++
+				if !sleepCtx(ctx, tailBackoff(attempt)) {
+					return
+				}
+				continue
+			}
+			attempt = 0
+
+			next := from
+			for _, m := range result.Metrics {
+				key := metricDedupKey(result.Columns, m)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				if m.Timestamp > next {
+					next = m.Timestamp
+				}
+				seen[key] = m.Timestamp
+
+				select {
+				case metrics <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// Only advance from when a later metric was actually seen: if
+			// we jumped straight to now, a point timestamped inside this
+			// poll's window but indexed by Zeus after the poll ran would
+			// never be retried on the next iteration.
+			from = next
+			for k, ts := range seen {
+				if ts < from {
+					delete(seen, k)
+				}
+			}
+
+			if !sleepCtx(ctx, interval) {
+				return
+			}
+		}
+	}()
+
+	return metrics, errs
+}
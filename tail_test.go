@@ -0,0 +1,212 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tailServer serves increasing log timestamps on /logs/goZeus/, failing
+// the second request with a 500 to simulate a transient disconnect.
+func tailServer() (*httptest.Server, *int32) {
+	var calls int32
+	var nextTimestamp int64 = 1
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&calls, 1)
+			if n == 2 {
+				w.WriteHeader(500)
+				return
+			}
+
+			ts := atomic.AddInt64(&nextTimestamp, 1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"total":1,"result":[{"timestamp":%d,"sequence_number":%d,"message":"m"}]}`, ts, ts)
+		}))
+	return server, &calls
+}
+
+func TestTailLogsResumesAfterDisconnect(t *testing.T) {
+	server, _ := tailServer()
+	defer server.Close()
+
+	zeus := &Zeus{ApiServ: server.URL, Token: "goZeus"}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	logs, errs := zeus.TailLogs(ctx, LogQuery{
+		LogName:       "app",
+		AttributeName: "message",
+		Pattern:       "m",
+		PollInterval:  10 * time.Millisecond,
+	})
+
+	var gotErr bool
+	nLogs := 0
+	seen := map[string]bool{}
+	for {
+		select {
+		case log, ok := <-logs:
+			if !ok {
+				logs = nil
+				break
+			}
+			nLogs++
+			key := logDedupKey(log)
+			if seen[key] {
+				t.Errorf("duplicate log delivered: %v", log)
+			}
+			seen[key] = true
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				break
+			}
+			if err != nil {
+				gotErr = true
+			}
+		}
+		if logs == nil && errs == nil {
+			break
+		}
+	}
+
+	// Only the second poll fails, so a second log can only come from a
+	// poll made after the simulated disconnect.
+	if nLogs < 2 {
+		t.Errorf("got %d logs, want at least 2 (one before and one after the disconnect)", nLogs)
+	}
+	if !gotErr {
+		t.Error("expected the simulated disconnect to surface an error")
+	}
+}
+
+// tailMetricsServer serves increasing metric timestamps on
+// /metrics/goZeus/_values/, failing the second request with a 500 to
+// simulate a transient disconnect.
+func tailMetricsServer() (*httptest.Server, *int32) {
+	var calls int32
+	var nextTimestamp int64 = 1
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&calls, 1)
+			if n == 2 {
+				w.WriteHeader(500)
+				return
+			}
+
+			ts := atomic.AddInt64(&nextTimestamp, 1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `[{"name":"cpu","columns":["time","value"],"points":[[%d,1]]}]`, ts)
+		}))
+	return server, &calls
+}
+
+func TestTailMetricsResumesAfterDisconnect(t *testing.T) {
+	server, _ := tailMetricsServer()
+	defer server.Close()
+
+	zeus := &Zeus{ApiServ: server.URL, Token: "goZeus"}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	metrics, errs := zeus.TailMetrics(ctx, MetricQuery{
+		MetricName:         "cpu",
+		AggregatorFunction: "max",
+		AggregatorColumn:   "value",
+		GroupInterval:      "1s",
+		PollInterval:       10 * time.Millisecond,
+	})
+
+	var gotErr bool
+	nMetrics := 0
+	seen := map[string]bool{}
+	for {
+		select {
+		case m, ok := <-metrics:
+			if !ok {
+				metrics = nil
+				break
+			}
+			nMetrics++
+			key := metricDedupKey([]string{"value"}, m)
+			if seen[key] {
+				t.Errorf("duplicate metric delivered: %v", m)
+			}
+			seen[key] = true
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				break
+			}
+			if err != nil {
+				gotErr = true
+			}
+		}
+		if metrics == nil && errs == nil {
+			break
+		}
+	}
+
+	// Only the second poll fails, so a second metric can only come from a
+	// poll made after the simulated disconnect.
+	if nMetrics < 2 {
+		t.Errorf("got %d metrics, want at least 2 (one before and one after the disconnect)", nMetrics)
+	}
+	if !gotErr {
+		t.Error("expected the simulated disconnect to surface an error")
+	}
+}
+
+func TestTailLogsStopsOnContextCancel(t *testing.T) {
+	server, _ := tailServer()
+	defer server.Close()
+
+	zeus := &Zeus{ApiServ: server.URL, Token: "goZeus"}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	logs, errs := zeus.TailLogs(ctx, LogQuery{
+		LogName:       "app",
+		AttributeName: "message",
+		Pattern:       "m",
+		PollInterval:  10 * time.Millisecond,
+	})
+
+	<-logs
+	cancel()
+
+	deadline := time.After(time.Second)
+	logsClosed, errsClosed := false, false
+	for !logsClosed || !errsClosed {
+		select {
+		case _, ok := <-logs:
+			if !ok {
+				logsClosed = true
+			}
+		case _, ok := <-errs:
+			if !ok {
+				errsClosed = true
+			}
+		case <-deadline:
+			t.Fatal("channels did not close after context cancellation")
+		}
+	}
+}
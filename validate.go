@@ -0,0 +1,89 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import "fmt"
+
+// validatePostLogs checks logs before it is sent to Zeus.
+func validatePostLogs(logs LogList) error {
+	if len(logs.Logs) == 0 {
+		return fieldError(ErrEmptyBatch, "logs")
+	}
+	return nil
+}
+
+// validateGetLogs checks GetLogs' required parameters and the sanity of
+// its time range.
+func validateGetLogs(logName, attributeName, pattern string, from, to int64) error {
+	switch {
+	case logName == "":
+		return fieldError(ErrInvalidArgument, "log_name")
+	case attributeName == "":
+		return fieldError(ErrInvalidArgument, "attribute_name")
+	case pattern == "":
+		return fieldError(ErrInvalidArgument, "pattern")
+	case to != 0 && to < from:
+		return fieldError(ErrInvalidArgument, "to")
+	}
+	return nil
+}
+
+// validatePostMetrics checks metrics before it is sent to Zeus, including
+// that every row has exactly as many points as there are columns.
+func validatePostMetrics(metrics MetricList) error {
+	if len(metrics.Metrics) == 0 {
+		return fieldError(ErrEmptyBatch, "metrics")
+	}
+	for i, m := range metrics.Metrics {
+		if len(m.Point) != len(metrics.Columns) {
+			return fieldError(ErrInvalidArgument, fmt.Sprintf("metrics[%d].points", i))
+		}
+	}
+	return nil
+}
+
+// validateGetMetricValues checks GetMetricValues' required parameters and
+// the sanity of its time range.
+func validateGetMetricValues(metricName, aggregatorFunction, aggregatorColumn, groupInterval string, from, to float64) error {
+	switch {
+	case metricName == "":
+		return fieldError(ErrInvalidArgument, "metric_name")
+	case aggregatorFunction == "":
+		return fieldError(ErrInvalidArgument, "aggregator_function")
+	case aggregatorColumn == "":
+		return fieldError(ErrInvalidArgument, "aggregator_column")
+	case groupInterval == "":
+		return fieldError(ErrInvalidArgument, "group_interval")
+	case to != 0 && to < from:
+		return fieldError(ErrInvalidArgument, "to")
+	}
+	return nil
+}
+
+// validateGetMetricNames checks GetMetricNames' required parameters.
+func validateGetMetricNames(metricName string) error {
+	if metricName == "" {
+		return fieldError(ErrInvalidArgument, "metric_name")
+	}
+	return nil
+}
+
+// validateDeleteMetrics checks DeleteMetrics' required parameters.
+func validateDeleteMetrics(metricName string) error {
+	if metricName == "" {
+		return fieldError(ErrInvalidArgument, "metric_name")
+	}
+	return nil
+}
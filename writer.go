@@ -0,0 +1,384 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BackpressurePolicy controls what a writer does when its queue is full.
+type BackpressurePolicy int
+
+const (
+	// Block makes Write wait until there is room in the queue.
+	Block BackpressurePolicy = iota
+	// DropOldest discards the oldest queued entry to make room for the new one.
+	DropOldest
+	// DropNewest discards the entry being written when the queue is full.
+	DropNewest
+)
+
+// WriterOptions configures the batching, flushing and retry behavior of a
+// LogWriter or MetricWriter.
+type WriterOptions struct {
+	// BatchSize is the number of entries accumulated before a batch is
+	// flushed to Zeus.
+	BatchSize int
+	// FlushInterval is the maximum time an entry waits in a partial batch
+	// before it is flushed, regardless of BatchSize.
+	FlushInterval time.Duration
+	// QueueSize is the number of entries buffered between Write and the
+	// background flush goroutine.
+	QueueSize int
+	// Backpressure selects what happens when the queue is full.
+	Backpressure BackpressurePolicy
+	// MaxRetries is the number of additional attempts made to post a batch
+	// after the first attempt fails. 0 means no retries.
+	MaxRetries int
+	// InitialBackoff bounds the delay before the first retry. The bound
+	// doubles for each subsequent retry, up to MaxBackoff, and each delay
+	// is drawn uniformly at random between 0 and the current bound.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// Observer, if set, is notified of batch sizes and retries. It lets
+	// callers wire in metrics (see PrometheusHook) without the writer
+	// depending on any particular backend.
+	Observer BatchObserver
+}
+
+// BatchObserver is notified of batching and retry activity by a LogWriter
+// or MetricWriter.
+type BatchObserver interface {
+	// ObserveBatchSize is called once per flush with the number of
+	// entries in the batch.
+	ObserveBatchSize(n int)
+	// ObserveRetry is called once per retried flush attempt.
+	ObserveRetry()
+}
+
+// DefaultWriterOptions returns the options used by NewLogWriter and
+// NewMetricWriter when none are given.
+func DefaultWriterOptions() WriterOptions {
+	return WriterOptions{
+		BatchSize:      100,
+		FlushInterval:  time.Second,
+		QueueSize:      1000,
+		Backpressure:   Block,
+		MaxRetries:     5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// retryable reports whether err is worth retrying: a network-level error,
+// or an *APIError for a 429 or 5xx response. Locally raised validation
+// errors (*APIError with Status == 0, e.g. ErrEmptyBatch/ErrInvalidArgument)
+// and other non-retryable 4xx responses will never succeed on retry, so
+// they're surfaced immediately instead of burning through MaxRetries.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.Status == http.StatusTooManyRequests || apiErr.Status >= http.StatusInternalServerError
+}
+
+// backoff returns the delay before retry attempt n (0-based), with full
+// jitter applied.
+func (o WriterOptions) backoff(n int) time.Duration {
+	d := o.InitialBackoff << uint(n)
+	if d <= 0 || d > o.MaxBackoff {
+		d = o.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// LogWriter batches Log values written to it and posts them to Zeus in
+// the background, retrying transient failures and applying backpressure
+// once its queue is full.
+type LogWriter struct {
+	zeus    *Zeus
+	logName string
+	opts    WriterOptions
+
+	queue chan Log
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu         sync.Mutex
+	successful int
+	failed     int
+}
+
+// NewLogWriter creates a LogWriter that posts batches of logs under
+// logName. A zero WriterOptions{} falls back to DefaultWriterOptions().
+func NewLogWriter(z *Zeus, logName string, opts WriterOptions) *LogWriter {
+	if opts == (WriterOptions{}) {
+		opts = DefaultWriterOptions()
+	}
+
+	w := &LogWriter{
+		zeus:    z,
+		logName: logName,
+		opts:    opts,
+		queue:   make(chan Log, opts.QueueSize),
+		done:    make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// Write enqueues log for delivery, applying the writer's backpressure
+// policy if the queue is full. It never blocks on network I/O.
+func (w *LogWriter) Write(log Log) {
+	switch w.opts.Backpressure {
+	case DropNewest:
+		select {
+		case w.queue <- log:
+		default:
+		}
+	case DropOldest:
+		for {
+			select {
+			case w.queue <- log:
+				return
+			default:
+				select {
+				case <-w.queue:
+				default:
+				}
+			}
+		}
+	default: // Block
+		w.queue <- log
+	}
+}
+
+// Close stops accepting new writes, drains and flushes the queue, and
+// returns the aggregate number of logs successfully and unsuccessfully
+// posted.
+func (w *LogWriter) Close() (successful, failed int) {
+	close(w.queue)
+	<-w.done
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.successful, w.failed
+}
+
+func (w *LogWriter) run() {
+	defer w.wg.Done()
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Log, 0, w.opts.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.post(batch)
+		batch = make([]Log, 0, w.opts.BatchSize)
+	}
+
+	for {
+		select {
+		case log, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, log)
+			if len(batch) >= w.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (w *LogWriter) post(batch []Log) {
+	logs := LogList{Name: w.logName, Logs: batch}
+	if w.opts.Observer != nil {
+		w.opts.Observer.ObserveBatchSize(len(batch))
+	}
+
+	var successful int
+	var err error
+	for attempt := 0; ; attempt++ {
+		successful, err = w.zeus.PostLogs(logs)
+		if err == nil || !retryable(err) || attempt >= w.opts.MaxRetries {
+			break
+		}
+		if w.opts.Observer != nil {
+			w.opts.Observer.ObserveRetry()
+		}
+		time.Sleep(w.opts.backoff(attempt))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.successful += successful
+	if err != nil {
+		w.failed += len(batch) - successful
+	}
+}
+
+// MetricWriter batches Metric values written to it and posts them to
+// Zeus in the background, retrying transient failures and applying
+// backpressure once its queue is full.
+type MetricWriter struct {
+	zeus       *Zeus
+	metricName string
+	columns    []string
+	opts       WriterOptions
+
+	queue chan Metric
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu         sync.Mutex
+	successful int
+	failed     int
+}
+
+// NewMetricWriter creates a MetricWriter that posts batches of metrics
+// under metricName with the given columns. A zero WriterOptions{} falls
+// back to DefaultWriterOptions().
+func NewMetricWriter(z *Zeus, metricName string, columns []string, opts WriterOptions) *MetricWriter {
+	if opts == (WriterOptions{}) {
+		opts = DefaultWriterOptions()
+	}
+
+	w := &MetricWriter{
+		zeus:       z,
+		metricName: metricName,
+		columns:    columns,
+		opts:       opts,
+		queue:      make(chan Metric, opts.QueueSize),
+		done:       make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// Write enqueues metric for delivery, applying the writer's backpressure
+// policy if the queue is full. It never blocks on network I/O.
+func (w *MetricWriter) Write(metric Metric) {
+	switch w.opts.Backpressure {
+	case DropNewest:
+		select {
+		case w.queue <- metric:
+		default:
+		}
+	case DropOldest:
+		for {
+			select {
+			case w.queue <- metric:
+				return
+			default:
+				select {
+				case <-w.queue:
+				default:
+				}
+			}
+		}
+	default: // Block
+		w.queue <- metric
+	}
+}
+
+// Close stops accepting new writes, drains and flushes the queue, and
+// returns the aggregate number of metrics successfully and
+// unsuccessfully posted.
+func (w *MetricWriter) Close() (successful, failed int) {
+	close(w.queue)
+	<-w.done
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.successful, w.failed
+}
+
+func (w *MetricWriter) run() {
+	defer w.wg.Done()
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Metric, 0, w.opts.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.post(batch)
+		batch = make([]Metric, 0, w.opts.BatchSize)
+	}
+
+	for {
+		select {
+		case metric, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, metric)
+			if len(batch) >= w.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (w *MetricWriter) post(batch []Metric) {
+	metrics := MetricList{Name: w.metricName, Columns: w.columns, Metrics: batch}
+	if w.opts.Observer != nil {
+		w.opts.Observer.ObserveBatchSize(len(batch))
+	}
+
+	var successful int
+	var err error
+	for attempt := 0; ; attempt++ {
+		successful, err = w.zeus.PostMetrics(metrics)
+		if err == nil || !retryable(err) || attempt >= w.opts.MaxRetries {
+			break
+		}
+		if w.opts.Observer != nil {
+			w.opts.Observer.ObserveRetry()
+		}
+		time.Sleep(w.opts.backoff(attempt))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.successful += successful
+	if err != nil {
+		w.failed += len(batch) - successful
+	}
+}
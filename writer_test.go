@@ -0,0 +1,161 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+package zeus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failUntil requests with a 500, then
+// succeeds, always reporting every posted log/metric as successful.
+func flakyServer(failUntil int32) (*httptest.Server, *Zeus) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&count, 1) <= failUntil {
+				w.WriteHeader(500)
+				return
+			}
+
+			r.ParseForm()
+			w.Header().Set("Content-Type", "application/json")
+			if logs := r.FormValue("logs"); logs != "" {
+				var ll LogList
+				json.Unmarshal([]byte(logs), &ll)
+				fmt.Fprintf(w, `{"successful": %d}`, len(ll.Logs))
+				return
+			}
+			var ml MetricList
+			json.Unmarshal([]byte(r.FormValue("metrics")), &ml)
+			fmt.Fprintf(w, `{"successful": %d}`, len(ml.Metrics))
+		}))
+	return server, &Zeus{ApiServ: server.URL, Token: "goZeus"}
+}
+
+func TestLogWriterRetriesTransientFailures(t *testing.T) {
+	server, zeus := flakyServer(2)
+	defer server.Close()
+
+	opts := DefaultWriterOptions()
+	opts.BatchSize = 1
+	opts.FlushInterval = 10 * time.Millisecond
+	opts.MaxRetries = 5
+	opts.InitialBackoff = time.Millisecond
+	opts.MaxBackoff = 5 * time.Millisecond
+
+	w := NewLogWriter(zeus, "retry-test", opts)
+	w.Write(Log{"message": "hello"})
+
+	successful, failed := w.Close()
+	if successful != 1 || failed != 0 {
+		t.Errorf("successful=%d failed=%d, want 1/0", successful, failed)
+	}
+}
+
+func TestLogWriterGivesUpAfterMaxRetries(t *testing.T) {
+	server, zeus := flakyServer(1000)
+	defer server.Close()
+
+	opts := DefaultWriterOptions()
+	opts.BatchSize = 1
+	opts.FlushInterval = 10 * time.Millisecond
+	opts.MaxRetries = 2
+	opts.InitialBackoff = time.Millisecond
+	opts.MaxBackoff = 5 * time.Millisecond
+
+	w := NewLogWriter(zeus, "fail-test", opts)
+	w.Write(Log{"message": "hello"})
+
+	successful, failed := w.Close()
+	if successful != 0 || failed != 1 {
+		t.Errorf("successful=%d failed=%d, want 0/1", successful, failed)
+	}
+}
+
+func TestMetricWriterDropNewest(t *testing.T) {
+	server, zeus := flakyServer(1000)
+	defer server.Close()
+
+	opts := DefaultWriterOptions()
+	opts.QueueSize = 1
+	opts.BatchSize = 1000 // never flush on size
+	opts.FlushInterval = time.Hour
+	opts.Backpressure = DropNewest
+
+	w := NewMetricWriter(zeus, "drop-test", []string{"value"}, opts)
+	for i := 0; i < 10; i++ {
+		w.Write(Metric{Point: []float64{float64(i)}})
+	}
+	w.Close()
+}
+
+// countingObserver counts ObserveRetry calls so tests can assert on
+// retry behavior without racing on timing.
+type countingObserver struct {
+	retries int32
+}
+
+func (o *countingObserver) ObserveBatchSize(n int) {}
+func (o *countingObserver) ObserveRetry()          { atomic.AddInt32(&o.retries, 1) }
+
+func TestMetricWriterDoesNotRetryValidationErrors(t *testing.T) {
+	zeus := &Zeus{ApiServ: "http://unused", Token: "goZeus"}
+
+	obs := &countingObserver{}
+	opts := DefaultWriterOptions()
+	opts.BatchSize = 1
+	opts.FlushInterval = 10 * time.Millisecond
+	opts.MaxRetries = 5
+	opts.InitialBackoff = time.Minute // would make the test hang if a retry is attempted
+	opts.MaxBackoff = time.Minute
+	opts.Observer = obs
+
+	w := NewMetricWriter(zeus, "invalid-test", []string{"col1", "col2"}, opts)
+	w.Write(Metric{Point: []float64{1.0}}) // length mismatch against columns
+
+	successful, failed := w.Close()
+	if successful != 0 || failed != 1 {
+		t.Errorf("successful=%d failed=%d, want 0/1", successful, failed)
+	}
+	if atomic.LoadInt32(&obs.retries) != 0 {
+		t.Errorf("retries = %d, want 0 for a local validation error", obs.retries)
+	}
+}
+
+func TestMetricWriterRetriesTransientFailures(t *testing.T) {
+	server, zeus := flakyServer(1)
+	defer server.Close()
+
+	opts := DefaultWriterOptions()
+	opts.BatchSize = 1
+	opts.FlushInterval = 10 * time.Millisecond
+	opts.MaxRetries = 5
+	opts.InitialBackoff = time.Millisecond
+	opts.MaxBackoff = 5 * time.Millisecond
+
+	w := NewMetricWriter(zeus, "retry-test", []string{"value"}, opts)
+	w.Write(Metric{Point: []float64{1.0}})
+
+	successful, failed := w.Close()
+	if successful != 1 || failed != 0 {
+		t.Errorf("successful=%d failed=%d, want 1/0", successful, failed)
+	}
+}
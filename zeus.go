@@ -0,0 +1,398 @@
+// Copyright 2015 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// 	Unless required by applicable law or agreed to in writing, software
+// 	distributed under the License is distributed on an "AS IS" BASIS,
+// 	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// 	See the License for the specific language governing permissions and
+// 	limitations under the License.
+
+// Package zeus is a Go client for the Zeus logging and metrics service.
+package zeus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Zeus holds the information needed to talk to a Zeus API server.
+type Zeus struct {
+	ApiServ string
+	Token   string
+
+	// Client is used to issue requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Deadline, if non-zero, bounds every call made through this Zeus. When
+	// the call's context carries a deadline of its own, the earlier of the
+	// two applies.
+	Deadline time.Duration
+
+	// Hooks are notified before and after every request this Zeus makes.
+	Hooks []Hook
+}
+
+// httpClient returns the http.Client to issue requests with, falling back
+// to http.DefaultClient when none was configured.
+func (z *Zeus) httpClient() *http.Client {
+	if z.Client != nil {
+		return z.Client
+	}
+	return http.DefaultClient
+}
+
+// withDeadline applies z.Deadline, if non-zero, to ctx; if ctx already
+// carries a deadline, the earlier of the two applies. The returned cancel
+// func must always be called.
+func (z *Zeus) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if z.Deadline == 0 {
+		if _, ok := ctx.Deadline(); ok {
+			return ctx, func() {}
+		}
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, z.Deadline)
+}
+
+// Log is a single log entry, keyed by attribute name.
+type Log map[string]interface{}
+
+// LogList is a named collection of log entries.
+type LogList struct {
+	Name string `json:"name,omitempty"`
+	Logs []Log  `json:"logs"`
+}
+
+// Metric is a single metric data point: a timestamp and the values of
+// every column other than "time".
+type Metric struct {
+	Timestamp float64   `json:"time,omitempty"`
+	Point     []float64 `json:"points,omitempty"`
+}
+
+// MetricList is a named collection of metrics sharing the same columns.
+type MetricList struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Metrics []Metric `json:"points"`
+}
+
+// metricResult mirrors the shape returned by Zeus for metric queries: a
+// "time" column followed by whatever columns the caller asked for.
+type metricResult struct {
+	Name    string      `json:"name"`
+	Columns []string    `json:"columns"`
+	Points  [][]float64 `json:"points"`
+}
+
+// logsResponse is the envelope returned by GetLogs.
+type logsResponse struct {
+	Total  int   `json:"total"`
+	Result []Log `json:"result"`
+}
+
+// successResponse is the envelope returned by PostLogs/PostMetrics.
+type successResponse struct {
+	Successful int `json:"successful"`
+}
+
+// PostLogs uploads a batch of logs under logs.Name. It returns the number
+// of logs the server accepted.
+func (z *Zeus) PostLogs(logs LogList) (int, error) {
+	return z.PostLogsContext(context.Background(), logs)
+}
+
+// PostLogsContext is PostLogs with a caller-supplied context, bounding the
+// call by ctx's deadline/cancellation in addition to z.Deadline.
+func (z *Zeus) PostLogsContext(ctx context.Context, logs LogList) (int, error) {
+	if err := validatePostLogs(logs); err != nil {
+		return 0, err
+	}
+
+	jsonStr, err := json.Marshal(logs)
+	if err != nil {
+		return 0, err
+	}
+
+	path := fmt.Sprintf("/logs/%s/%s/", z.Token, logs.Name)
+	body := url.Values{"logs": {string(jsonStr)}}
+
+	var resp successResponse
+	if err := z.post(ctx, path, body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Successful, nil
+}
+
+// GetLogs searches logName for attributeName values matching pattern
+// within [from, to], returning at most limit results starting at offset.
+func (z *Zeus) GetLogs(logName, attributeName, pattern string, from, to int64,
+	offset, limit int) (int, LogList, error) {
+	return z.GetLogsContext(context.Background(), logName, attributeName, pattern, from, to, offset, limit)
+}
+
+// GetLogsContext is GetLogs with a caller-supplied context, bounding the
+// call by ctx's deadline/cancellation in addition to z.Deadline.
+func (z *Zeus) GetLogsContext(ctx context.Context, logName, attributeName, pattern string,
+	from, to int64, offset, limit int) (int, LogList, error) {
+
+	if err := validateGetLogs(logName, attributeName, pattern, from, to); err != nil {
+		return 0, LogList{}, err
+	}
+
+	param := url.Values{
+		"log_name":       {logName},
+		"attribute_name": {attributeName},
+		"pattern":        {pattern},
+		"from":           {strconv.FormatInt(from, 10)},
+		"to":             {strconv.FormatInt(to, 10)},
+	}
+	if offset != 0 {
+		param.Set("offset", strconv.Itoa(offset))
+	}
+	if limit != 0 {
+		param.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := fmt.Sprintf("/logs/%s/", z.Token)
+
+	var resp logsResponse
+	if err := z.get(ctx, path, param, &resp); err != nil {
+		return 0, LogList{}, err
+	}
+	return resp.Total, LogList{Name: logName, Logs: resp.Result}, nil
+}
+
+// PostMetrics uploads a batch of metrics under metrics.Name. It returns
+// the number of metrics the server accepted.
+func (z *Zeus) PostMetrics(metrics MetricList) (int, error) {
+	return z.PostMetricsContext(context.Background(), metrics)
+}
+
+// PostMetricsContext is PostMetrics with a caller-supplied context,
+// bounding the call by ctx's deadline/cancellation in addition to
+// z.Deadline.
+func (z *Zeus) PostMetricsContext(ctx context.Context, metrics MetricList) (int, error) {
+	if err := validatePostMetrics(metrics); err != nil {
+		return 0, err
+	}
+
+	jsonStr, err := json.Marshal(metrics)
+	if err != nil {
+		return 0, err
+	}
+
+	path := fmt.Sprintf("/metrics/%s/%s/", z.Token, metrics.Name)
+	body := url.Values{"metrics": {string(jsonStr)}}
+
+	var resp successResponse
+	if err := z.post(ctx, path, body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Successful, nil
+}
+
+// GetMetricNames lists metric names matching metricName, returning at
+// most limit names starting at offset.
+func (z *Zeus) GetMetricNames(metricName string, offset, limit int) ([]string, error) {
+	return z.GetMetricNamesContext(context.Background(), metricName, offset, limit)
+}
+
+// GetMetricNamesContext is GetMetricNames with a caller-supplied context,
+// bounding the call by ctx's deadline/cancellation in addition to
+// z.Deadline.
+func (z *Zeus) GetMetricNamesContext(ctx context.Context, metricName string, offset, limit int) ([]string, error) {
+	if err := validateGetMetricNames(metricName); err != nil {
+		return nil, err
+	}
+
+	param := url.Values{"metric_name": {metricName}}
+	if offset != 0 {
+		param.Set("offset", strconv.Itoa(offset))
+	}
+	if limit != 0 {
+		param.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := fmt.Sprintf("/metrics/%s/_names/", z.Token)
+
+	var names []string
+	if err := z.get(ctx, path, param, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// GetMetricValues aggregates metricName's aggregatorColumn values via
+// aggregatorFunction, grouped by groupInterval, within [from, to] and
+// matching filterCondition.
+func (z *Zeus) GetMetricValues(metricName, aggregatorFunction, aggregatorColumn,
+	groupInterval string, from, to float64, filterCondition string,
+	offset, limit int) (MetricList, error) {
+	return z.GetMetricValuesContext(context.Background(), metricName, aggregatorFunction,
+		aggregatorColumn, groupInterval, from, to, filterCondition, offset, limit)
+}
+
+// GetMetricValuesContext is GetMetricValues with a caller-supplied
+// context, bounding the call by ctx's deadline/cancellation in addition
+// to z.Deadline.
+func (z *Zeus) GetMetricValuesContext(ctx context.Context, metricName, aggregatorFunction,
+	aggregatorColumn, groupInterval string, from, to float64, filterCondition string,
+	offset, limit int) (MetricList, error) {
+
+	if err := validateGetMetricValues(metricName, aggregatorFunction, aggregatorColumn, groupInterval, from, to); err != nil {
+		return MetricList{}, err
+	}
+
+	param := url.Values{
+		"metric_name":         {metricName},
+		"aggregator_function": {aggregatorFunction},
+		"aggregator_column":   {aggregatorColumn},
+		"group_interval":      {groupInterval},
+		"from":                {strconv.FormatFloat(from, 'f', 3, 64)},
+		"to":                  {strconv.FormatFloat(to, 'f', 3, 64)},
+		"filter_condition":    {filterCondition},
+	}
+	if offset != 0 {
+		param.Set("offset", strconv.Itoa(offset))
+	}
+	if limit != 0 {
+		param.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := fmt.Sprintf("/metrics/%s/_values/", z.Token)
+
+	var results []metricResult
+	if err := z.get(ctx, path, param, &results); err != nil {
+		return MetricList{}, err
+	}
+	if len(results) == 0 {
+		return MetricList{}, nil
+	}
+
+	result := results[0]
+	list := MetricList{Name: result.Name}
+	if len(result.Columns) > 0 {
+		list.Columns = result.Columns[1:]
+	}
+	for _, point := range result.Points {
+		if len(point) == 0 {
+			continue
+		}
+		list.Metrics = append(list.Metrics, Metric{
+			Timestamp: point[0],
+			Point:     point[1:],
+		})
+	}
+	return list, nil
+}
+
+// DeleteMetrics deletes every metric under metricName.
+func (z *Zeus) DeleteMetrics(metricName string) (bool, error) {
+	return z.DeleteMetricsContext(context.Background(), metricName)
+}
+
+// DeleteMetricsContext is DeleteMetrics with a caller-supplied context,
+// bounding the call by ctx's deadline/cancellation in addition to
+// z.Deadline.
+func (z *Zeus) DeleteMetricsContext(ctx context.Context, metricName string) (bool, error) {
+	if err := validateDeleteMetrics(metricName); err != nil {
+		return false, err
+	}
+
+	ctx, cancel := z.withDeadline(ctx)
+	defer cancel()
+
+	path := fmt.Sprintf("/metrics/%s/%s/", z.Token, metricName)
+
+	req, err := http.NewRequest("DELETE", z.ApiServ+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+
+	z.fireBefore("DELETE", path)
+	start := time.Now()
+	resp, err := z.httpClient().Do(req)
+	if err != nil {
+		z.fireAfter("DELETE", path, 0, start, err)
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(resp.Body)
+		err = newAPIError(resp, data)
+	}
+	z.fireAfter("DELETE", path, resp.StatusCode, start, err)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// post issues a form-encoded POST to path and decodes the JSON response
+// into out.
+func (z *Zeus) post(ctx context.Context, path string, body url.Values, out interface{}) error {
+	ctx, cancel := z.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequest("POST", z.ApiServ+path, strings.NewReader(body.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(ctx)
+
+	z.fireBefore("POST", path)
+	start := time.Now()
+	resp, err := z.httpClient().Do(req)
+	if err != nil {
+		z.fireAfter("POST", path, 0, start, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = decodeResponse(resp, out)
+	z.fireAfter("POST", path, resp.StatusCode, start, err)
+	return err
+}
+
+// get issues a GET with query parameters to path and decodes the JSON
+// response into out.
+func (z *Zeus) get(ctx context.Context, path string, param url.Values, out interface{}) error {
+	ctx, cancel := z.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", z.ApiServ+path+"?"+param.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	z.fireBefore("GET", path)
+	start := time.Now()
+	resp, err := z.httpClient().Do(req)
+	if err != nil {
+		z.fireAfter("GET", path, 0, start, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = decodeResponse(resp, out)
+	z.fireAfter("GET", path, resp.StatusCode, start, err)
+	return err
+}
+